@@ -0,0 +1,206 @@
+package minima
+
+/**
+* Minima is a free and open source software under Mit license
+
+Copyright (c) 2021 gominima
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+* Authors @apoorvcodes @megatank58
+* Maintainers @Panquesito7 @savioxavier @Shubhaankar-Sharma @apoorvcodes @megatank58
+* Thank you for showing interest in minima and for this beautiful community
+*/
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+/**
+ * @info The request header minima reads an inbound request id from, and sets
+ * on the response, so a caller can correlate a request across services
+*/
+const RequestIDHeader = "X-Request-Id"
+
+/**
+ * @info AccessLogEntry is a single request's worth of access log data, handed
+ * to an AccessLogger once ServeHTTP has finished running the handler
+ * @property {string} [Method] The http method of the request
+ * @property {string} [Path] The request path
+ * @property {int} [Status] The response status code
+ * @property {int} [Bytes] The number of response body bytes written
+ * @property {time.Duration} [Duration] How long the request took to serve
+ * @property {string} [RemoteAddr] The remote address of the caller
+ * @property {string} [RequestID] The request id correlated across the request's lifetime
+*/
+type AccessLogEntry struct {
+	Method     string
+	Path       string
+	Status     int
+	Bytes      int
+	Duration   time.Duration
+	RemoteAddr string
+	RequestID  string
+}
+
+/**
+ * @info AccessLogger receives one AccessLogEntry per request, implementations
+ * must be safe for concurrent use since ServeHTTP may call Log from many
+ * goroutines at once
+*/
+type AccessLogger interface {
+	Log(entry AccessLogEntry)
+}
+
+/**
+ * @info jsonAccessLogger is the default AccessLogger, it writes one JSON
+ * object per line to out
+ * @property {io.Writer} [out] The destination the log lines are written to
+ * @property {sync.Mutex} [mu] Serializes writes so concurrent requests don't interleave lines
+*/
+type jsonAccessLogger struct {
+	out io.Writer
+	mu  sync.Mutex
+}
+
+type accessLogRecord struct {
+	Method     string  `json:"method"`
+	Path       string  `json:"path"`
+	Status     int     `json:"status"`
+	Bytes      int     `json:"bytes"`
+	DurationMs float64 `json:"duration_ms"`
+	RemoteAddr string  `json:"remote_addr"`
+	RequestID  string  `json:"request_id"`
+}
+
+/**
+ * @info Makes a new AccessLogger that writes newline delimited JSON to out
+ * @param {io.Writer} [out] The destination the log lines are written to
+ * @returns {AccessLogger}
+*/
+func NewJSONAccessLogger(out io.Writer) AccessLogger {
+	return &jsonAccessLogger{out: out}
+}
+
+func (l *jsonAccessLogger) Log(entry AccessLogEntry) {
+	line, err := json.Marshal(accessLogRecord{
+		Method:     entry.Method,
+		Path:       entry.Path,
+		Status:     entry.Status,
+		Bytes:      entry.Bytes,
+		DurationMs: float64(entry.Duration) / float64(time.Millisecond),
+		RemoteAddr: entry.RemoteAddr,
+		RequestID:  entry.RequestID,
+	})
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(line)
+}
+
+/**
+ * @info statusWriter wraps a http.ResponseWriter to record the status code and
+ * byte count of a response, so ServeHTTP can report them to the access logger
+ * without needing to see inside the route's Response
+ * @property {http.ResponseWriter} [ResponseWriter] The wrapped net/http response writer
+ * @property {int} [status] The status code the handler wrote, 0 until a header is written
+ * @property {int} [bytes] The number of body bytes written so far
+ * @property {bool} [wroteHeader] Whether WriteHeader has already run
+*/
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+type requestIDKeyType struct{}
+
+var requestIDKey requestIDKeyType
+
+var requestSeq uint64
+
+/**
+ * @info Generates a request id made of a monotonic sequence number and a
+ * random suffix, unique within a process and cheap to produce on every request
+ * @returns {string}
+*/
+func newRequestID() string {
+	seq := atomic.AddUint64(&requestSeq, 1)
+
+	var suffix [4]byte
+	if _, err := rand.Read(suffix[:]); err != nil {
+		return fmt.Sprintf("%x", seq)
+	}
+
+	return fmt.Sprintf("%x-%s", seq, hex.EncodeToString(suffix[:]))
+}
+
+/**
+ * @info Returns a copy of ctx carrying id as the request id
+ * @param {context.Context} [ctx] The parent context
+ * @param {string} [id] The request id to attach
+ * @returns {context.Context}
+*/
+func withRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+/**
+ * @info Reads the request id previously attached with withRequestID, so
+ * downstream handlers and middleware can log under the same id, returns ""
+ * if none was attached
+ * @param {context.Context} [ctx] The context to read from
+ * @returns {string}
+*/
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
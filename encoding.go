@@ -0,0 +1,230 @@
+package minima
+
+/**
+* Minima is a free and open source software under Mit license
+
+Copyright (c) 2021 gominima
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+* Authors @apoorvcodes @megatank58
+* Maintainers @Panquesito7 @savioxavier @Shubhaankar-Sharma @apoorvcodes @megatank58
+* Thank you for showing interest in minima and for this beautiful community
+*/
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/google/brotli/go/cbrotli"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+/**
+ * @info Encoder serializes a payload onto w for a single negotiated content type
+*/
+type Encoder interface {
+	Encode(w io.Writer, payload interface{}) error
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) Encode(w io.Writer, payload interface{}) error {
+	return json.NewEncoder(w).Encode(payload)
+}
+
+type xmlEncoder struct{}
+
+func (xmlEncoder) Encode(w io.Writer, payload interface{}) error {
+	return xml.NewEncoder(w).Encode(payload)
+}
+
+type msgpackEncoder struct{}
+
+func (msgpackEncoder) Encode(w io.Writer, payload interface{}) error {
+	return msgpack.NewEncoder(w).Encode(payload)
+}
+
+var (
+	encoderMu sync.RWMutex
+	encoders  = map[string]Encoder{
+		"application/json":    jsonEncoder{},
+		"application/xml":     xmlEncoder{},
+		"application/msgpack": msgpackEncoder{},
+	}
+)
+
+/**
+@info Registers enc as the encoder used for mime when a request negotiates it
+via Response.Negotiate, overwriting any encoder already registered for mime
+@param {string} [mime] The content type the encoder produces, e.g. "application/json"
+@param {Encoder} [enc] The encoder implementation
+@returns {*minima}
+*/
+func (m *minima) RegisterEncoder(mime string, enc Encoder) *minima {
+	encoderMu.Lock()
+	encoders[mime] = enc
+	encoderMu.Unlock()
+	return m
+}
+
+func encoderFor(mime string) (Encoder, bool) {
+	encoderMu.RLock()
+	defer encoderMu.RUnlock()
+	enc, ok := encoders[mime]
+	return enc, ok
+}
+
+/**
+ * @info Picks the first registered content type listed in the given Accept
+ * header, in header order, falling back to application/json if nothing
+ * registered matches. q-values are not weighed
+ * @param {string} [accept] The incoming request's Accept header
+ * @returns {string}
+*/
+func negotiateContentType(accept string) string {
+	for _, candidate := range strings.Split(accept, ",") {
+		mime := strings.TrimSpace(strings.SplitN(candidate, ";", 2)[0])
+		if mime == "" || mime == "*/*" {
+			continue
+		}
+		if _, ok := encoderFor(mime); ok {
+			return mime
+		}
+	}
+	return "application/json"
+}
+
+/**
+ * @info Picks the transparent content encoding for the given Accept-Encoding
+ * header, br is preferred over gzip when both are listed. q-values are not
+ * read, so a token listed with q=0 still counts as accepted
+ * @param {string} [acceptEncoding] The incoming request's Accept-Encoding header
+ * @returns {string}
+*/
+func negotiateEncoding(acceptEncoding string) string {
+	var hasGzip, hasBrotli bool
+	for _, candidate := range strings.Split(acceptEncoding, ",") {
+		switch strings.TrimSpace(strings.SplitN(candidate, ";", 2)[0]) {
+		case "br":
+			hasBrotli = true
+		case "gzip":
+			hasGzip = true
+		}
+	}
+
+	switch {
+	case hasBrotli:
+		return "br"
+	case hasGzip:
+		return "gzip"
+	default:
+		return ""
+	}
+}
+
+var gzipWriterPool = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(io.Discard) },
+}
+
+func getGzipWriter(w io.Writer) *gzip.Writer {
+	gz := gzipWriterPool.Get().(*gzip.Writer)
+	gz.Reset(w)
+	return gz
+}
+
+func putGzipWriter(gz *gzip.Writer) {
+	gz.Close()
+	gzipWriterPool.Put(gz)
+}
+
+/**
+ * @info brotliWriter pools the small wrapper around a *cbrotli.Writer, the
+ * underlying C encoder state itself can't be reset between requests so this
+ * only saves the wrapper allocation, not the encoder's internal buffers
+*/
+type brotliWriter struct {
+	w *cbrotli.Writer
+}
+
+var brotliWriterPool = sync.Pool{
+	New: func() interface{} { return new(brotliWriter) },
+}
+
+func getBrotliWriter(w io.Writer) *brotliWriter {
+	bw := brotliWriterPool.Get().(*brotliWriter)
+	bw.w = cbrotli.NewWriter(w, cbrotli.WriterOptions{Quality: 5})
+	return bw
+}
+
+func putBrotliWriter(bw *brotliWriter) {
+	bw.w.Close()
+	bw.w = nil
+	brotliWriterPool.Put(bw)
+}
+
+func (bw *brotliWriter) Write(p []byte) (int, error) {
+	return bw.w.Write(p)
+}
+
+/**
+@info Negotiate encodes payload using the encoder matching the request's
+Accept header, registered via minima.RegisterEncoder, defaulting to JSON when
+nothing else matches. It also transparently applies gzip or br compression
+when the request's Accept-Encoding allows it. gzip writers are pooled, so a
+gzip response doesn't allocate a fresh compressor; a br response builds a new
+cbrotli encoder on every call, only its small wrapper is pooled
+@param {interface{}} [payload] The value to encode and send as the response body
+@returns {error}
+*/
+func (res *Response) Negotiate(payload interface{}) error {
+	req := res.Request()
+
+	mime := negotiateContentType(req.Header.Get("Accept"))
+	enc, ok := encoderFor(mime)
+	if !ok {
+		mime = "application/json"
+		enc = jsonEncoder{}
+	}
+	res.Set("Content-Type", mime)
+
+	var body io.Writer = res
+	streamed := false
+	switch negotiateEncoding(req.Header.Get("Accept-Encoding")) {
+	case "gzip":
+		res.Set("Content-Encoding", "gzip")
+		gz := getGzipWriter(res)
+		defer putGzipWriter(gz)
+		body = gz
+		streamed = true
+	case "br":
+		res.Set("Content-Encoding", "br")
+		bw := getBrotliWriter(res)
+		defer putBrotliWriter(bw)
+		body = bw
+		streamed = true
+	}
+	res.BaseHeaders(streamed)
+
+	return enc.Encode(body, payload)
+}
@@ -0,0 +1,46 @@
+package minima
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+)
+
+type benchPayload struct {
+	Data string `json:"data"`
+}
+
+func fourKBPayload() benchPayload {
+	return benchPayload{Data: strings.Repeat("a", 4096)}
+}
+
+func BenchmarkGzipEncodePooled(b *testing.B) {
+	payload := fourKBPayload()
+	var buf bytes.Buffer
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		gz := getGzipWriter(&buf)
+		if err := (jsonEncoder{}).Encode(gz, payload); err != nil {
+			b.Fatal(err)
+		}
+		putGzipWriter(gz)
+	}
+}
+
+func BenchmarkGzipEncodeNaive(b *testing.B) {
+	payload := fourKBPayload()
+	var buf bytes.Buffer
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		gz := gzip.NewWriter(&buf)
+		if err := (jsonEncoder{}).Encode(gz, payload); err != nil {
+			b.Fatal(err)
+		}
+		gz.Close()
+	}
+}
@@ -2,9 +2,19 @@ package minima
 
 import (
 	"context"
+	"crypto/tls"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+	"golang.org/x/net/http2"
 )
 
 /**
@@ -16,16 +26,24 @@ import (
 @property {map[string]interface{}} [properties] The properties for the server instance
 @property {*Config} [Config] The core config file for middlewares and router instances
 @property {*time.Duration} [drain] The router's drain time
+@property {sync.WaitGroup} [inflight] Tracks in-flight requests so shutdown can drain them
+@property {AccessLogger} [accessLog] The access logger requests are reported to, nil disables logging
+@property {context.Context} [shutdownCtx] Cancelled once the drain deadline passed to Shutdown expires, so in-flight handlers watching their request context can bail out
+@property {context.CancelFunc} [cancelShutdown] Cancels shutdownCtx
 */
 type minima struct {
-	server     *http.Server
-	started    bool
-	Timeout    time.Duration
-	router     *Router
-	properties map[string]interface{}
-	Config     *Config
-	Middleware *Plugins
-	drain      time.Duration
+	server         *http.Server
+	started        bool
+	Timeout        time.Duration
+	router         *Router
+	properties     map[string]interface{}
+	Config         *Config
+	Middleware     *Plugins
+	drain          time.Duration
+	inflight       sync.WaitGroup
+	accessLog      AccessLogger
+	shutdownCtx    context.Context
+	cancelShutdown context.CancelFunc
 }
 
 /**
@@ -44,11 +62,16 @@ func main() {
 @returns {minima}
 */
 func New() *minima {
+	shutdownCtx, cancelShutdown := context.WithCancel(context.Background())
+
 	return &minima{
-		router:     NewRouter(),
-		Config:     NewConfig(),
-		Middleware: use(),
-		drain:      0,
+		router:         NewRouter(),
+		Config:         NewConfig(),
+		Middleware:     use(),
+		properties:     make(map[string]interface{}),
+		drain:          0,
+		shutdownCtx:    shutdownCtx,
+		cancelShutdown: cancelShutdown,
 	}
 }
 
@@ -68,6 +91,157 @@ func (m *minima) Listen(addr string) error {
 
 }
 
+/**
+@info Starts the http server and blocks until one of signals (SIGINT and
+SIGTERM by default) is received, at which point it stops accepting new
+connections and waits up to m.drain for in-flight requests to finish before
+returning, with no time limit when no drain timeout has been set
+@param {string} [addr] The port for the server instance to run on
+@param {...os.Signal} [signals] The signals to shut down on, defaults to SIGINT and SIGTERM
+@returns {error}
+*/
+func (m *minima) ListenAndGracefulShutdown(addr string, signals ...os.Signal) error {
+	if m.started {
+		log.Panicf("Minimia's instance is already running at %s.", m.server.Addr)
+	}
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	m.server = &http.Server{Addr: addr, Handler: m}
+	m.started = true
+
+	serveErr := make(chan error, 1)
+	go func() {
+		if err := m.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, signals...)
+	defer signal.Stop(sigCh)
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-sigCh:
+		log.Println("Received shutdown signal, draining in-flight requests")
+	}
+
+	ctx := context.Background()
+	if m.drain > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.drain)
+		defer cancel()
+	}
+
+	return m.Shutdown(ctx)
+}
+
+// autocertCacheProp is the SetProp key used to override the directory
+// autocert caches issued certificates in, defaults to defaultAutocertCacheDir
+const autocertCacheProp = "autocert.cache"
+
+const defaultAutocertCacheDir = "/var/lib/minima/certs"
+
+/**
+@info Starts the http server with TLS, serving HTTP/2 over the TLS connection,
+using the given certificate and key files
+@param {string} [addr] The port for the server instance to run on
+@param {string} [certFile] Path to the PEM encoded certificate file
+@param {string} [keyFile] Path to the PEM encoded private key file
+@returns {error}
+*/
+func (m *minima) ListenTLS(addr, certFile, keyFile string) error {
+	if m.started {
+		log.Panicf("Minimia's instance is already running at %s.", m.server.Addr)
+	}
+
+	m.server = &http.Server{
+		Addr:      addr,
+		Handler:   m,
+		TLSConfig: &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+	if err := http2.ConfigureServer(m.server, &http2.Server{}); err != nil {
+		return err
+	}
+	m.started = true
+
+	return m.server.ListenAndServeTLS(certFile, keyFile)
+}
+
+/**
+@info Starts the http server with TLS certificates obtained and renewed
+automatically via ACME for the given hosts, the cache directory defaults to
+defaultAutocertCacheDir and can be overridden with SetProp("autocert.cache", dir)
+before calling
+@param {string} [addr] The port for the server instance to run on
+@param {...string} [hosts] The hostnames autocert is allowed to request certificates for
+@returns {error}
+*/
+func (m *minima) ListenAutoTLS(addr string, hosts ...string) error {
+	if m.started {
+		log.Panicf("Minimia's instance is already running at %s.", m.server.Addr)
+	}
+
+	cacheDir := defaultAutocertCacheDir
+	if dir, ok := m.GetProp(autocertCacheProp).(string); ok && dir != "" {
+		cacheDir = dir
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(hosts...),
+		Cache:      autocert.DirCache(cacheDir),
+	}
+
+	m.server = &http.Server{
+		Addr:      addr,
+		Handler:   m,
+		TLSConfig: manager.TLSConfig(),
+	}
+	if err := http2.ConfigureServer(m.server, &http2.Server{}); err != nil {
+		return err
+	}
+	m.started = true
+
+	return m.server.ListenAndServeTLS("", "")
+}
+
+/**
+@info Runs a plain http server on httpAddr that answers every request with a
+301 redirect to the same host and path on httpsAddr, meant to run alongside
+ListenTLS or ListenAutoTLS so plain http requests are bounced onto https
+@param {string} [httpAddr] The port for the redirecting http server to run on
+@param {string} [httpsAddr] The https listener, host optional, port used for the redirect target
+@returns {error}
+*/
+func (m *minima) ListenTLSRedirect(httpAddr, httpsAddr string) error {
+	_, httpsPort, err := net.SplitHostPort(httpsAddr)
+	if err != nil {
+		httpsPort = httpsAddr
+	}
+
+	redirect := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+
+		target := "https://" + host
+		if httpsPort != "" && httpsPort != "443" {
+			target += ":" + httpsPort
+		}
+		target += r.URL.RequestURI()
+
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	})
+
+	return (&http.Server{Addr: httpAddr, Handler: redirect}).ListenAndServe()
+}
+
 /**
 @info Injects the actual minima server logic to http
 @param {http.ResponseWriter} [w] The net/http response instance
@@ -75,7 +249,36 @@ func (m *minima) Listen(addr string) error {
 @returns {}
 */
 func (m *minima) ServeHTTP(w http.ResponseWriter, q *http.Request) {
-	f, params, match := m.router.routes[q.Method].Get(q.URL.Path)
+	m.inflight.Add(1)
+	defer m.inflight.Done()
+
+	start := time.Now()
+	id := q.Header.Get(RequestIDHeader)
+	if id == "" {
+		id = newRequestID()
+	}
+	ctx, cancel := context.WithCancel(withRequestID(q.Context(), id))
+	defer cancel()
+	go func() {
+		select {
+		case <-m.shutdownCtx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	q = q.WithContext(ctx)
+
+	sw := &statusWriter{ResponseWriter: w}
+	sw.Header().Set(RequestIDHeader, id)
+
+	routes, ok := m.router.routes[q.Method]
+
+	var f Handler
+	var params *Params
+	var match bool
+	if ok {
+		f, params, match = routes.Get(q.URL.Path)
+	}
 
 	if match {
 		if err := q.ParseForm(); err != nil {
@@ -83,22 +286,85 @@ func (m *minima) ServeHTTP(w http.ResponseWriter, q *http.Request) {
 			return
 		}
 
-		res := response(w, q, &m.properties)
+		res := response(sw, q, &m.properties)
 		req := request(q)
-		req.Params = params
+		req.Params = *params
 
 		m.Middleware.ServePlugin(res, req)
 		f(res, req)
+		PutParams(params)
+		res.Flush()
+		m.logAccess(q, sw, start, id)
+		return
+	}
+
+	if allowed := m.allowedMethods(q.Method, q.URL.Path); len(allowed) > 0 {
+		sw.Header().Set("Allow", strings.Join(allowed, ", "))
+		sw.WriteHeader(http.StatusMethodNotAllowed)
+		m.logAccess(q, sw, start, id)
+		return
+	}
+
+	res := response(sw, q, &m.properties)
+	req := request(q)
+	if m.router.notfound != nil {
+		m.router.notfound(res, req)
+		res.Flush()
 	} else {
-		res := response(w, q, &m.properties)
-		req := request(q)
-		if m.router.notfound != nil {
-			m.router.notfound(res, req)
-		} else {
-			w.Write([]byte("No matching route found"))
-		}
+		sw.Write([]byte("No matching route found"))
+	}
+	m.logAccess(q, sw, start, id)
+}
+
+/**
+@info Reports a finished request to the configured access logger, a no-op if
+none was installed via UseAccessLog
+@param {*http.Request} [q] The request that was served
+@param {*statusWriter} [sw] The response writer the request was served through
+@param {time.Time} [start] When the request started being served
+@param {string} [id] The request id assigned to the request
+@returns {}
+*/
+func (m *minima) logAccess(q *http.Request, sw *statusWriter, start time.Time, id string) {
+	if m.accessLog == nil {
+		return
+	}
 
+	status := sw.status
+	if status == 0 {
+		status = http.StatusOK
 	}
+
+	m.accessLog.Log(AccessLogEntry{
+		Method:     q.Method,
+		Path:       q.URL.Path,
+		Status:     status,
+		Bytes:      sw.bytes,
+		Duration:   time.Since(start),
+		RemoteAddr: q.RemoteAddr,
+		RequestID:  id,
+	})
+}
+
+/**
+@info Finds the methods, other than the one requested, whose tree has a route
+matching path, used to answer with 405 and an Allow header instead of falling
+through to the notfound handler
+@param {string} [method] The method of the incoming request
+@param {string} [path] The path of the incoming request
+@returns {[]string}
+*/
+func (m *minima) allowedMethods(method, path string) []string {
+	var allowed []string
+	for candidate, routes := range m.router.routes {
+		if candidate == method {
+			continue
+		}
+		if routes.Has(path) {
+			allowed = append(allowed, candidate)
+		}
+	}
+	return allowed
 }
 
 /**
@@ -188,6 +454,18 @@ func (m *minima) Use(handler Handler) *minima {
 	return m
 }
 
+/**
+@info Injects the given access logger into the core instance, every request is
+reported to it once its handler has finished running, as is every 405 and
+not-found answer. A matched request whose form fails to parse is not reported
+@param {AccessLogger} [logger] The access logger instance
+@returns {*minima}
+*/
+func (m *minima) UseAccessLog(logger AccessLogger) *minima {
+	m.accessLog = logger
+	return m
+}
+
 /**
 @info Injects the NotFound handler to the minima instance
 @param {Handler} [handler] Minima handler instance
@@ -247,13 +525,36 @@ func (m *minima) ShutdownTimeout(t time.Duration) *minima {
 }
 
 /**
-@info Shutdowns the core instance
+@info Shutdowns the core instance, stops accepting new connections and waits
+for requests already in flight to finish, or for ctx to expire, whichever
+comes first. If ctx expires first, shutdownCtx is cancelled too, so any
+in-flight handler watching its request context sees it cancelled instead of
+being left to run in the background indefinitely
 @param {context.Context} [ctx] The context for shutdown
 @returns {error}
 */
 func (m *minima) Shutdown(ctx context.Context) error {
 	log.Println("Stopping the server")
-	return m.server.Shutdown(ctx)
+	if err := m.server.Shutdown(ctx); err != nil {
+		if ctx.Err() != nil {
+			m.cancelShutdown()
+		}
+		return err
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		m.inflight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		m.cancelShutdown()
+		return ctx.Err()
+	}
 }
 
 /**
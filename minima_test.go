@@ -0,0 +1,27 @@
+package minima
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServeHTTPMethodNotAllowed(t *testing.T) {
+	m := New()
+	m.Get("/users", func(res *Response, req *Request) {})
+	m.Post("/users", func(res *Response, req *Request) {})
+
+	req := httptest.NewRequest(http.MethodDelete, "/users", nil)
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("got status %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+
+	allow := rec.Header().Get("Allow")
+	if !strings.Contains(allow, http.MethodGet) || !strings.Contains(allow, http.MethodPost) {
+		t.Fatalf("got Allow header %q, want it to list GET and POST", allow)
+	}
+}
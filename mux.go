@@ -29,37 +29,103 @@ SOFTWARE.
 */
 
 import (
+	"fmt"
 	"strings"
+	"sync"
 )
 
 /**
- * @info The Param structure
- * @property {string} [name] The name of the param
- * @property {bool} [fixed] Whether the param is fixed or not
+ * @info The kind of segment a node matches
 */
-type param struct {
-	name  string
-	fixed bool
+type nodeKind uint8
+
+const (
+	staticNode nodeKind = iota
+	paramNode
+	catchAllNode
+)
+
+/**
+ * @info The Param structure, a single matched path parameter
+ * @property {string} [Key] The name of the param
+ * @property {string} [Value] The value extracted from the request path
+*/
+type Param struct {
+	Key   string
+	Value string
 }
 
 /**
- * @info The Route structure
- * @property {string} [prefix] The prefix of the route
- * @property {[]param} [partnames] The route paths split into parts
- * @property {Handler} [function] The handler to be used
+ * @info Params is the ordered list of path parameters matched for a request,
+ * preallocated and reused via a sync.Pool so routing a request does not
+ * allocate a map[string]string on every call
 */
-type Route struct {
-	prefix    string
-	partNames []param
-	function  Handler
+type Params []Param
+
+/**
+ * @info Get returns the value of the named param, or "" if it isn't present
+ * @param {string} [name] Name of the param
+ * @returns {string}
+*/
+func (p Params) Get(name string) string {
+	for _, kv := range p {
+		if kv.Key == name {
+			return kv.Value
+		}
+	}
+	return ""
+}
+
+var paramsPool = sync.Pool{
+	New: func() interface{} {
+		params := make(Params, 0, 8)
+		return &params
+	},
+}
+
+func getParams() *Params {
+	return paramsPool.Get().(*Params)
+}
+
+/**
+ * @info PutParams releases a Params slice obtained from a route lookup back to
+ * the pool, it must only be called once the caller is done reading the params
+ * @param {*Params} [params] The params slice to release
+*/
+func PutParams(params *Params) {
+	if params == nil {
+		return
+	}
+	*params = (*params)[:0]
+	paramsPool.Put(params)
 }
 
 /**
- * @info The Routes root structure
- * @property {map[string][]Route} [roots] The map of array routes
+ * @info node is a single segment of the radix tree, static children are
+ * matched literally while param and catch-all children bind their matched
+ * text into the request's Params
+ * @property {nodeKind} [kind] The kind of segment this node matches
+ * @property {string} [segment] The static text, or param/catch-all name
+ * @property {[]*node} [statics] Static children, matched literally
+ * @property {*node} [param] The single param child, if any
+ * @property {*node} [catchAll] The single catch-all child, if any
+ * @property {Handler} [handler] The handler registered at this node, if any
+*/
+type node struct {
+	kind     nodeKind
+	segment  string
+	statics  []*node
+	param    *node
+	catchAll *node
+	handler  Handler
+}
+
+/**
+ * @info Routes is a per-method radix tree of registered routes
+ * @property {*node} [root] The root of the tree
 */
 type Routes struct {
-	roots map[string][]Route
+	root *node
 }
 
 /**
@@ -67,130 +133,124 @@ type Routes struct {
  * @returns {*Routes}
 */
 func NewRoutes() *Routes {
-	return &Routes{
-		roots: make(map[string][]Route),
-	}
+	return &Routes{root: &node{}}
 }
 
 /**
- * @info Adds a new route to the routes table
+ * @info Adds a new route to the routes tree
  * @param {string} [path] Path of the route
  * @param {Handler} [handler] Handler of the route
 */
 func (r *Routes) Add(path string, f Handler) {
-	parts := strings.Split(path, "/")
-	var rootParts []string
-	var varParts []param
-	var paramsFound bool
-	for _, p := range parts {
-		if strings.HasPrefix(p, ":") {
-			paramsFound = true
-		}
-
-		if paramsFound {
-			if strings.HasPrefix(p, ":") {
-				varParts = append(varParts, param{
-					name:  strings.TrimPrefix(p, ":"),
-					fixed: false,
-				})
-			} else {
-				varParts = append(varParts, param{
-					name:  p,
-					fixed: true,
-				})
+	cur := r.root
+	for _, seg := range splitPath(path) {
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			name := strings.TrimPrefix(seg, ":")
+			if cur.param == nil {
+				cur.param = &node{kind: paramNode, segment: name}
+			} else if cur.param.segment != name {
+				panic(fmt.Sprintf("minima: path %q conflicts with an already registered route, param is named %q there, not %q", path, cur.param.segment, name))
+			}
+			cur = cur.param
+		case strings.HasPrefix(seg, "*"):
+			name := strings.TrimPrefix(seg, "*")
+			if cur.catchAll == nil {
+				cur.catchAll = &node{kind: catchAllNode, segment: name}
+			} else if cur.catchAll.segment != name {
+				panic(fmt.Sprintf("minima: path %q conflicts with an already registered route, catch-all is named %q there, not %q", path, cur.catchAll.segment, name))
 			}
-		} else {
-			rootParts = append(rootParts, p)
+			cur = cur.catchAll
+		default:
+			cur = cur.addStatic(seg)
 		}
 	}
+	cur.handler = f
+}
 
-	root := strings.Join(rootParts, "/")
-
-	r.roots[root] = append(r.roots[root], Route{
-		prefix:    root,
-		partNames: varParts,
-		function:  f,
-	})
+func (n *node) addStatic(segment string) *node {
+	for _, c := range n.statics {
+		if c.segment == segment {
+			return c
+		}
+	}
+	child := &node{kind: staticNode, segment: segment}
+	n.statics = append(n.statics, child)
+	return child
 }
 
 /**
- * @info Gets handler and params from the routes table
+ * @info Gets handler and params from the routes tree, the returned Params must
+ * be released with PutParams once the caller is finished reading them
  * @param {string} [path] Path of the route to find
- * @returns {Handler, map[string]string, bool}
+ * @returns {Handler, *Params, bool}
 */
-func (r *Routes) Get(path string) (Handler, map[string]string, bool) {
-	var routes []Route
-	remaining := path
-	for {
-		var ok bool
-		routes, ok = r.roots[remaining]
-		if ok {
-			return matchRoutes(path, routes)
+func (r *Routes) Get(path string) (Handler, *Params, bool) {
+	params := getParams()
+	if h, ok := r.root.match(splitPath(path), params); ok {
+		return h, params, true
+	}
+	PutParams(params)
+	return nil, nil, false
+}
 
-		}
+/**
+ * @info Has reports whether path matches any registered route in this tree,
+ * regardless of which handler serves it, used to build the Allow header on a
+ * 405 response
+ * @param {string} [path] Path of the route to test
+ * @returns {bool}
+*/
+func (r *Routes) Has(path string) bool {
+	params := getParams()
+	_, ok := r.root.match(splitPath(path), params)
+	PutParams(params)
+	return ok
+}
 
-		if len(remaining) < 2 {
-			return nil, nil, false
+func (n *node) match(segments []string, params *Params) (Handler, bool) {
+	if len(segments) == 0 {
+		if n.handler != nil {
+			return n.handler, true
 		}
+		return nil, false
+	}
 
-		index := strings.LastIndex(remaining, "/")
-		if index < 0 {
-			return nil, nil, false
-		}
+	seg, rest := segments[0], segments[1:]
 
-		if index > 0 {
-			remaining = remaining[:index]
-		} else {
-			remaining = "/"
+	for _, c := range n.statics {
+		if c.segment == seg {
+			if h, ok := c.match(rest, params); ok {
+				return h, true
+			}
 		}
 	}
-}
 
-/**
- * @info Matches routes to the request
- * @param {string} [path] Path of the request route to find
- * @param {[]Route} [routes] The array of routes to match
- * @returns {Handler, map[string]string, bool}
-*/
-func matchRoutes(path string, routes []Route) (Handler, map[string]string, bool) {
-outer:
-	for _, r := range routes {
-		params := strings.Split(
-			strings.TrimPrefix(
-				strings.TrimPrefix(path, r.prefix),
-				"/"),
-			"/")
-		valid := cleanArray(params)
-
-		if len(valid) == len(r.partNames) {
-			paramNames := make(map[string]string)
-			for i, p := range r.partNames {
-				if p.fixed {
-					if params[i] != p.name {
-						continue outer
-					} else {
-						continue
-					}
-				}
-				paramNames[p.name] = params[i]
-			}
-			return r.function, paramNames, true
+	if n.param != nil {
+		*params = append(*params, Param{Key: n.param.segment, Value: seg})
+		if h, ok := n.param.match(rest, params); ok {
+			return h, true
 		}
+		*params = (*params)[:len(*params)-1]
 	}
-	return nil, nil, false
+
+	if n.catchAll != nil && n.catchAll.handler != nil {
+		*params = append(*params, Param{Key: n.catchAll.segment, Value: strings.Join(segments, "/")})
+		return n.catchAll.handler, true
+	}
+
+	return nil, false
 }
 
 /**
- * @info Cleans the array and finds non nill values
- * @param {string} [path] The array of string to slice and clean
+ * @info Splits a path into its non empty segments
+ * @param {string} [path] The path to split
  * @returns {[]string}
 */
-func cleanArray(a []string) []string {
-	var valid []string
-	for _, s := range a {
-		if s != "" {
-			valid = append(valid, s)
-		}
+func splitPath(path string) []string {
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		return nil
 	}
-	return valid
+	return strings.Split(trimmed, "/")
 }
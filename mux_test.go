@@ -0,0 +1,71 @@
+package minima
+
+import "testing"
+
+func noopHandler(res *Response, req *Request) {}
+
+func TestRoutesParamExtraction(t *testing.T) {
+	routes := NewRoutes()
+	routes.Add("/users/:id", noopHandler)
+
+	_, params, ok := routes.Get("/users/42")
+	if !ok {
+		t.Fatal("expected /users/42 to match")
+	}
+	defer PutParams(params)
+
+	if got := params.Get("id"); got != "42" {
+		t.Fatalf("got id=%q, want \"42\"", got)
+	}
+}
+
+func TestRoutesCatchAll(t *testing.T) {
+	routes := NewRoutes()
+	routes.Add("/files/*rest", noopHandler)
+
+	_, params, ok := routes.Get("/files/a/b/c")
+	if !ok {
+		t.Fatal("expected /files/a/b/c to match")
+	}
+	defer PutParams(params)
+
+	if got := params.Get("rest"); got != "a/b/c" {
+		t.Fatalf("got rest=%q, want \"a/b/c\"", got)
+	}
+}
+
+func TestRoutesConflictingParamNamePanics(t *testing.T) {
+	routes := NewRoutes()
+	routes.Add("/users/:id", noopHandler)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected registering /users/:slug to panic")
+		}
+	}()
+	routes.Add("/users/:slug", noopHandler)
+}
+
+func TestRoutesConflictingCatchAllNamePanics(t *testing.T) {
+	routes := NewRoutes()
+	routes.Add("/files/*rest", noopHandler)
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected registering /files/*path to panic")
+		}
+	}()
+	routes.Add("/files/*path", noopHandler)
+}
+
+func TestRoutesHasForAllowHeader(t *testing.T) {
+	routes := NewRoutes()
+	routes.Add("/users/:id", noopHandler)
+
+	if !routes.Has("/users/42") {
+		t.Fatal("expected Has to report a match for /users/42")
+	}
+	if routes.Has("/users") {
+		t.Fatal("expected Has to report no match for /users")
+	}
+}
@@ -0,0 +1,229 @@
+package minima
+
+/**
+* Minima is a free and open source software under Mit license
+
+Copyright (c) 2021 gominima
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+* Authors @apoorvcodes @megatank58
+* Maintainers @Panquesito7 @savioxavier @Shubhaankar-Sharma @apoorvcodes @megatank58
+* Thank you for showing interest in minima and for this beautiful community
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+/**
+ * @info SchemaDoc is a minimal, hand-authored stand-in for an OpenAPI schema
+ * object, enough to describe the shapes minima's built-in encoders produce
+ * @property {string} [Type] The JSON Schema type, e.g. "object", "string", "integer"
+ * @property {string} [Format] An optional format hint, e.g. "int64", "date-time"
+ * @property {*SchemaDoc} [Items] The item schema, for Type "array"
+ * @property {map[string]SchemaDoc} [Properties] Property schemas, for Type "object"
+*/
+type SchemaDoc struct {
+	Type       string               `json:"type,omitempty"`
+	Format     string               `json:"format,omitempty"`
+	Items      *SchemaDoc           `json:"items,omitempty"`
+	Properties map[string]SchemaDoc `json:"properties,omitempty"`
+}
+
+/**
+ * @info MediaTypeDoc describes the schema returned for a single content type
+ * @property {SchemaDoc} [Schema] The schema for this content type
+*/
+type MediaTypeDoc struct {
+	Schema SchemaDoc `json:"schema"`
+}
+
+/**
+ * @info ParameterDoc describes a single request parameter
+ * @property {string} [Name] The parameter name, must match a ":name" or "*name" route segment when In is "path"
+ * @property {string} [In] Where the parameter is carried: "path", "query" or "header"
+ * @property {string} [Description] A human readable description
+ * @property {bool} [Required] Whether the parameter must be present
+ * @property {SchemaDoc} [Schema] The parameter's schema
+*/
+type ParameterDoc struct {
+	Name        string    `json:"name"`
+	In          string    `json:"in"`
+	Description string    `json:"description,omitempty"`
+	Required    bool      `json:"required,omitempty"`
+	Schema      SchemaDoc `json:"schema"`
+}
+
+/**
+ * @info RequestBodyDoc describes the body of a request
+ * @property {string} [Description] A human readable description
+ * @property {bool} [Required] Whether the body must be present
+ * @property {map[string]MediaTypeDoc} [Content] Schemas keyed by content type, e.g. "application/json"
+*/
+type RequestBodyDoc struct {
+	Description string                  `json:"description,omitempty"`
+	Required    bool                    `json:"required,omitempty"`
+	Content     map[string]MediaTypeDoc `json:"content"`
+}
+
+/**
+ * @info ResponseDoc describes a single possible response
+ * @property {string} [Description] A human readable description, required by the OpenAPI spec
+ * @property {map[string]MediaTypeDoc} [Content] Schemas keyed by content type, e.g. "application/json"
+*/
+type ResponseDoc struct {
+	Description string                  `json:"description"`
+	Content     map[string]MediaTypeDoc `json:"content,omitempty"`
+}
+
+/**
+ * @info OperationDoc is the user-supplied OpenAPI description of a single
+ * route, attached with Router.Describe, minima never infers this from the
+ * handler itself
+ * @property {string} [Summary] A short summary of the operation
+ * @property {string} [Description] A longer description of the operation
+ * @property {string} [OperationID] A unique id for the operation
+ * @property {[]string} [Tags] OpenAPI tags for grouping in documentation UIs
+ * @property {[]ParameterDoc} [Parameters] The operation's parameters
+ * @property {*RequestBodyDoc} [RequestBody] The operation's request body, if any
+ * @property {map[string]ResponseDoc} [Responses] Responses keyed by status code, e.g. "200"
+*/
+type OperationDoc struct {
+	Summary     string                 `json:"summary,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	OperationID string                 `json:"operationId,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Parameters  []ParameterDoc         `json:"parameters,omitempty"`
+	RequestBody *RequestBodyDoc        `json:"requestBody,omitempty"`
+	Responses   map[string]ResponseDoc `json:"responses,omitempty"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIDocument struct {
+	OpenAPI string                            `json:"openapi"`
+	Info    openAPIInfo                       `json:"info"`
+	Paths   map[string]map[string]OperationDoc `json:"paths"`
+}
+
+/**
+@info Builds an OpenAPI 3.0 document from every route registered on the core
+instance's router, including routes adopted through Mount/UseRouter and routes
+on groups carved off with Router.Group. Only routes with a doc attached via
+Router.Describe are included
+@returns {[]byte, error}
+*/
+func (m *minima) OpenAPI() ([]byte, error) {
+	doc := openAPIDocument{
+		OpenAPI: "3.0.3",
+		Info:    openAPIInfo{Title: "minima", Version: "0.0.0"},
+		Paths:   make(map[string]map[string]OperationDoc),
+	}
+
+	docs := m.router.allDocs()
+	for _, reg := range m.router.allRegistrations() {
+		op, ok := docs[docKey(reg.method, reg.path)]
+		if !ok {
+			continue
+		}
+
+		oasPath := toOpenAPIPath(reg.path)
+		if doc.Paths[oasPath] == nil {
+			doc.Paths[oasPath] = make(map[string]OperationDoc)
+		}
+		doc.Paths[oasPath][strings.ToLower(reg.method)] = op
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+/**
+ * @info Converts a minima route path's ":name" and "*name" segments into the
+ * OpenAPI "{name}" form
+ * @param {string} [path] The route path, as registered
+ * @returns {string}
+*/
+func toOpenAPIPath(path string) string {
+	segments := splitPath(path)
+	for i, seg := range segments {
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			segments[i] = "{" + strings.TrimPrefix(seg, ":") + "}"
+		case strings.HasPrefix(seg, "*"):
+			segments[i] = "{" + strings.TrimPrefix(seg, "*") + "}"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+/**
+@info Registers a route on the core instance that serves its own generated
+OpenAPI document as JSON
+@param {string} [path] The route path the spec is served from
+@returns {*minima}
+*/
+func (m *minima) ServeOpenAPI(path string) *minima {
+	m.router.Get(path, func(res *Response, req *Request) {
+		spec, err := m.OpenAPI()
+		if err != nil {
+			res.Status(500).Send(fmt.Sprintf("failed to generate OpenAPI document: %s", err))
+			return
+		}
+		res.Set("Content-Type", "application/json")
+		res.Status(200).Send(string(spec))
+	})
+	return m
+}
+
+const swaggerUITemplate = `<!DOCTYPE html>
+<html>
+<head>
+	<title>minima API docs</title>
+	<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist/swagger-ui.css">
+</head>
+<body>
+	<div id="swagger-ui"></div>
+	<script src="https://unpkg.com/swagger-ui-dist/swagger-ui-bundle.js"></script>
+	<script>
+		window.onload = () => SwaggerUIBundle({ url: %q, dom_id: "#swagger-ui" })
+	</script>
+</body>
+</html>`
+
+/**
+@info Registers a route on the core instance that serves a Swagger UI page
+pointed at specPath, which should already be served via ServeOpenAPI
+@param {string} [path] The route path the UI page is served from
+@param {string} [specPath] The route path the OpenAPI document is served from
+@returns {*minima}
+*/
+func (m *minima) ServeSwaggerUI(path, specPath string) *minima {
+	page := fmt.Sprintf(swaggerUITemplate, specPath)
+	m.router.Get(path, func(res *Response, req *Request) {
+		res.Set("Content-Type", "text/html;charset=utf-8")
+		res.Status(200).Send(page)
+	})
+	return m
+}
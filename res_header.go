@@ -33,15 +33,21 @@ import (
 )
 
 /**
- * @info The Outgoing header structure
+ * @info The Outgoing header structure, the status code set via Status is
+ * buffered and only written to res on the first Write or Flush, so Status can
+ * be called many times and still be observed afterwards, e.g. by an access logger
  * @property {http.Request} [req] The net/http request instance
  * @property {http.ResponseWriter} [res] The net/http response instance
- * @property {bool} [body] Whether body has been sent or not
- * @property {int} [status] response status code
+ * @property {int} [status] The buffered response status code
+ * @property {bool} [wroteHeader] Whether the status has been written to res yet
+ * @property {int} [bytes] The number of body bytes written so far
 */
 type OutgoingHeader struct {
-	req *http.Request
-	res http.ResponseWriter
+	req         *http.Request
+	res         http.ResponseWriter
+	status      int
+	wroteHeader bool
+	bytes       int
 }
 
 var statusCodes = map[string]int{
@@ -80,7 +86,7 @@ var statusCodes = map[string]int{
  * @returns {OutgoingHeader}
 */
 func NewResHeader(res http.ResponseWriter, req *http.Request) *OutgoingHeader {
-	return &OutgoingHeader{req, res}
+	return &OutgoingHeader{req: req, res: res}
 }
 
 /**
@@ -132,22 +138,79 @@ func (h *OutgoingHeader) Setlength(len string) *OutgoingHeader {
 }
 
 /**
- * @info Sets response status
+ * @info Buffers the response status, it is not written to res until the first
+ * Write or Flush, so it can be changed or inspected right up until then
  * @param {int} [code] The status code for the response
  * @returns {OutgoingHeader}
 */
 func (h *OutgoingHeader) Status(code int) *OutgoingHeader {
-	h.res.WriteHeader(code)
+	h.status = code
 	return h
 }
 
 /**
- * @info Sends good stack of base headers
+ * @info StatusCode returns the status that has been set so far, defaulting to
+ * 200 if Status hasn't been called yet, regardless of whether it has actually
+ * been written to res
+ * @returns {int}
+*/
+func (h *OutgoingHeader) StatusCode() int {
+	if h.status == 0 {
+		return 200
+	}
+	return h.status
+}
+
+/**
+ * @info BytesWritten returns the number of response body bytes written so far
+ * @returns {int}
+*/
+func (h *OutgoingHeader) BytesWritten() int {
+	return h.bytes
+}
+
+/**
+ * @info Sends good stack of base headers, transfer-encoding is only advertised
+ * as chunked when streamed is true, a handler sending a fixed length body
+ * should set Content-Length instead
+ * @param {bool} [streamed] Whether the handler streams the body rather than sending it all at once
  * @returns {}
 */
-func (h *OutgoingHeader) BaseHeaders() {
-	h.Set("transfer-encoding", "chunked")
+func (h *OutgoingHeader) BaseHeaders(streamed bool) {
 	h.Set("connection", "keep-alive")
+	if streamed {
+		h.Set("transfer-encoding", "chunked")
+	}
+}
+
+/**
+ * @info Returns the net/http request this header belongs to, so code holding
+ * only the response side can still read request headers, e.g. for content negotiation
+ * @returns {*http.Request}
+*/
+func (h *OutgoingHeader) Request() *http.Request {
+	return h.req
+}
+
+/**
+ * @info Writes the buffered status and any body bytes to res, the status is
+ * only written once, on the first call
+ * @param {[]byte} [b] The body bytes to write
+ * @returns {int, error}
+*/
+func (h *OutgoingHeader) Write(b []byte) (int, error) {
+	h.writeHeader()
+	n, err := h.res.Write(b)
+	h.bytes += n
+	return n, err
+}
+
+func (h *OutgoingHeader) writeHeader() {
+	if h.wroteHeader {
+		return
+	}
+	h.wroteHeader = true
+	h.res.WriteHeader(h.StatusCode())
 }
 
 /**
@@ -159,6 +222,8 @@ func (h *OutgoingHeader) Flush() bool {
 		h.Set("Content-Type", "text/html;charset=utf-8")
 	}
 
+	h.writeHeader()
+
 	if f, ok := h.res.(http.Flusher); ok {
 		f.Flush()
 	}
@@ -0,0 +1,391 @@
+package minima
+
+/**
+* Minima is a free and open source software under Mit license
+
+Copyright (c) 2021 gominima
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+
+* Authors @apoorvcodes @megatank58
+* Maintainers @Panquesito7 @savioxavier @Shubhaankar-Sharma @apoorvcodes @megatank58
+* Thank you for showing interest in minima and for this beautiful community
+*/
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+/**
+ * @info registration records a single route as it was added, kept alongside
+ * the radix tree so Mount/UseRouter can re-register it under a new prefix
+ * @property {string} [method] The http method of the route
+ * @property {string} [path] The fully prefixed path of the route
+ * @property {Handler} [handler] The handler, already wrapped with its router's middleware
+*/
+type registration struct {
+	method  string
+	path    string
+	handler Handler
+}
+
+/**
+ * @info The Router structure
+ * @property {map[string]*Routes} [routes] Per-method route trees
+ * @property {Handler} [notfound] The handler run when no route matches
+ * @property {string} [prefix] The path prefix routes registered on this router are nested under
+ * @property {[]Handler} [middleware] The middleware stack scoped to this router
+ * @property {[]registration} [registrations] Every route registered on this router, used when mounting
+ * @property {map[string]OperationDoc} [docs] OpenAPI docs attached via Describe, keyed by method and full path
+ * @property {[]*Router} [children] Groups carved off this router with Group, walked when collecting routes for OpenAPI
+*/
+type Router struct {
+	routes        map[string]*Routes
+	notfound      Handler
+	prefix        string
+	middleware    []Handler
+	registrations []registration
+	docs          map[string]OperationDoc
+	children      []*Router
+}
+
+/**
+ * @info Makes a new Router instance
+ * @returns {*Router}
+*/
+func NewRouter() *Router {
+	return &Router{
+		routes: make(map[string]*Routes),
+	}
+}
+
+/**
+ * @info Group returns a child router whose routes are nested under prefix and
+ * run middleware before the route handler, in addition to any middleware
+ * already scoped to the parent. The child shares the parent's route trees, so
+ * routes registered on it are served directly, no separate Mount is needed
+ * @param {string} [prefix] The path prefix for every route registered on the group
+ * @param {...Handler} [middleware] The middleware stack scoped to the group
+ * @returns {*Router}
+*/
+func (r *Router) Group(prefix string, middleware ...Handler) *Router {
+	group := &Router{
+		routes:   r.routes,
+		notfound: r.notfound,
+		prefix:   joinPath(r.prefix, prefix),
+	}
+	group.middleware = make([]Handler, 0, len(r.middleware)+len(middleware))
+	group.middleware = append(group.middleware, r.middleware...)
+	group.middleware = append(group.middleware, middleware...)
+	r.children = append(r.children, group)
+	return group
+}
+
+func (r *Router) register(method, path string, handler Handler) {
+	full := joinPath(r.prefix, path)
+	wrapped := r.wrap(handler)
+
+	if r.routes[method] == nil {
+		r.routes[method] = NewRoutes()
+	}
+	r.routes[method].Add(full, wrapped)
+	r.registrations = append(r.registrations, registration{method: method, path: full, handler: wrapped})
+}
+
+/**
+ * @info wrap composes the router's middleware stack in front of handler, each
+ * middleware can short-circuit the chain by ending the response before
+ * returning
+ * @param {Handler} [handler] The route handler to wrap
+ * @returns {Handler}
+*/
+func (r *Router) wrap(handler Handler) Handler {
+	if len(r.middleware) == 0 {
+		return handler
+	}
+	middleware := r.middleware
+	return func(res *Response, req *Request) {
+		for _, h := range middleware {
+			h(res, req)
+			if res.Ended() {
+				return
+			}
+		}
+		handler(res, req)
+	}
+}
+
+/**
+@info Adds route with Get method
+@param {string} [path] The route path
+@param {Handler} [handler] The handler for the given route
+@returns {*Router}
+*/
+func (r *Router) Get(path string, handler Handler) *Router {
+	r.register(http.MethodGet, path, handler)
+	return r
+}
+
+/**
+@info Adds route with Put method
+@param {string} [path] The route path
+@param {Handler} [handler] The handler for the given route
+@returns {*Router}
+*/
+func (r *Router) Put(path string, handler Handler) *Router {
+	r.register(http.MethodPut, path, handler)
+	return r
+}
+
+/**
+@info Adds route with Options method
+@param {string} [path] The route path
+@param {Handler} [handler] The handler for the given route
+@returns {*Router}
+*/
+func (r *Router) Options(path string, handler Handler) *Router {
+	r.register(http.MethodOptions, path, handler)
+	return r
+}
+
+/**
+@info Adds route with Head method
+@param {string} [path] The route path
+@param {Handler} [handler] The handler for the given route
+@returns {*Router}
+*/
+func (r *Router) Head(path string, handler Handler) *Router {
+	r.register(http.MethodHead, path, handler)
+	return r
+}
+
+/**
+@info Adds route with Delete method
+@param {string} [path] The route path
+@param {Handler} [handler] The handler for the given route
+@returns {*Router}
+*/
+func (r *Router) Delete(path string, handler Handler) *Router {
+	r.register(http.MethodDelete, path, handler)
+	return r
+}
+
+/**
+@info Adds route with Patch method
+@param {string} [path] The route path
+@param {Handler} [handler] The handler for the given route
+@returns {*Router}
+*/
+func (r *Router) Patch(path string, handler Handler) *Router {
+	r.register(http.MethodPatch, path, handler)
+	return r
+}
+
+/**
+@info Adds route with Post method
+@param {string} [path] The route path
+@param {Handler} [handler] The handler for the given route
+@returns {*Router}
+*/
+func (r *Router) Post(path string, handler Handler) *Router {
+	r.register(http.MethodPost, path, handler)
+	return r
+}
+
+/**
+@info Attaches an OpenAPI operation doc to the route registered at method and
+path on this router, path is relative to the router the same way it is for
+Get/Post/etc. Panics if op declares a path parameter that isn't actually part
+of the route
+@param {string} [path] The route path, relative to this router
+@param {string} [method] The http method of the route, e.g. http.MethodGet
+@param {OperationDoc} [op] The OpenAPI operation doc for the route
+@returns {*Router}
+*/
+func (r *Router) Describe(path, method string, op OperationDoc) *Router {
+	full := joinPath(r.prefix, path)
+	if err := validateOperationDoc(full, op); err != nil {
+		panic(err)
+	}
+	r.setDoc(method, full, op)
+	return r
+}
+
+func (r *Router) setDoc(method, path string, op OperationDoc) {
+	if r.docs == nil {
+		r.docs = make(map[string]OperationDoc)
+	}
+	r.docs[docKey(method, path)] = op
+}
+
+func docKey(method, path string) string {
+	return method + " " + path
+}
+
+/**
+ * @info Validates that every path-scoped parameter declared in op is actually
+ * present as a param or catch-all segment on path
+ * @param {string} [path] The full, prefixed route path
+ * @param {OperationDoc} [op] The OpenAPI operation doc to validate
+ * @returns {error}
+*/
+func validateOperationDoc(path string, op OperationDoc) error {
+	known := make(map[string]bool)
+	for _, name := range pathParamNames(path) {
+		known[name] = true
+	}
+
+	for _, p := range op.Parameters {
+		if p.In == "path" && !known[p.Name] {
+			return fmt.Errorf("minima: OpenAPI doc for %s declares path parameter %q, which isn't part of the route", path, p.Name)
+		}
+	}
+	return nil
+}
+
+/**
+ * @info Extracts the names of a path's param (":name") and catch-all ("*name")
+ * segments, in order
+ * @param {string} [path] The path to inspect
+ * @returns {[]string}
+*/
+func pathParamNames(path string) []string {
+	var names []string
+	for _, seg := range splitPath(path) {
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			names = append(names, strings.TrimPrefix(seg, ":"))
+		case strings.HasPrefix(seg, "*"):
+			names = append(names, strings.TrimPrefix(seg, "*"))
+		}
+	}
+	return names
+}
+
+/**
+ * @info Collects every registration registered on this router and, recursively,
+ * on any groups carved off it
+ * @returns {[]registration}
+*/
+func (r *Router) allRegistrations() []registration {
+	all := make([]registration, len(r.registrations))
+	copy(all, r.registrations)
+	for _, child := range r.children {
+		all = append(all, child.allRegistrations()...)
+	}
+	return all
+}
+
+/**
+ * @info Collects the OpenAPI docs attached on this router and, recursively, on
+ * any groups carved off it, keyed the same way Describe stores them
+ * @returns {map[string]OperationDoc}
+*/
+func (r *Router) allDocs() map[string]OperationDoc {
+	all := make(map[string]OperationDoc)
+	for key, op := range r.docs {
+		all[key] = op
+	}
+	for _, child := range r.children {
+		for key, op := range child.allDocs() {
+			all[key] = op
+		}
+	}
+	return all
+}
+
+/**
+@info Injects the NotFound handler to the router instance
+@param {Handler} [handler] Minima handler instance
+@returns {*Router}
+*/
+func (r *Router) NotFound(handler Handler) *Router {
+	r.notfound = handler
+	return r
+}
+
+/**
+@info Merges every route already registered on router into r, as-is, with no
+added prefix
+@param {*Router} [router] Minima router instance
+@returns {*Router}
+*/
+func (r *Router) UseRouter(router *Router) *Router {
+	docs := router.allDocs()
+	for _, reg := range router.allRegistrations() {
+		r.adopt(docs, reg, reg.path)
+	}
+	if router.notfound != nil {
+		r.notfound = router.notfound
+	}
+	return r
+}
+
+/**
+@info Mounts router's routes onto r under path, prefixing every registered
+route rather than sharing a routes tree the way Group does
+@param {string} [path] The route path
+@param {*Router} [router] Minima router instance
+@returns {*Router}
+*/
+func (r *Router) Mount(path string, router *Router) *Router {
+	base := joinPath(r.prefix, path)
+	docs := router.allDocs()
+	for _, reg := range router.allRegistrations() {
+		r.adopt(docs, reg, joinPath(base, reg.path))
+	}
+	return r
+}
+
+/**
+ * @info adopt registers a route carried over from another router's
+ * registrations under newPath, also carrying over its OpenAPI doc, if any,
+ * re-keyed to match. docs is the full, already flattened doc set of the
+ * router reg came from (see Router.allDocs), so docs attached on a group
+ * nested inside that router are carried over too
+ * @param {map[string]OperationDoc} [docs] The origin router's flattened docs, keyed by method and its original path
+ * @param {registration} [reg] The registration being copied
+ * @param {string} [newPath] The path to register reg's handler under on r
+*/
+func (r *Router) adopt(docs map[string]OperationDoc, reg registration, newPath string) {
+	if r.routes[reg.method] == nil {
+		r.routes[reg.method] = NewRoutes()
+	}
+	r.routes[reg.method].Add(newPath, reg.handler)
+	r.registrations = append(r.registrations, registration{method: reg.method, path: newPath, handler: reg.handler})
+
+	if op, ok := docs[docKey(reg.method, reg.path)]; ok {
+		r.setDoc(reg.method, newPath, op)
+	}
+}
+
+/**
+ * @info Joins a router prefix and a route path into a single clean path
+ * @param {string} [prefix] The router prefix, may be empty
+ * @param {string} [path] The route path
+ * @returns {string}
+*/
+func joinPath(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
@@ -0,0 +1,86 @@
+package minima
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func runRoute(r *Router, method, path string) (*Response, bool) {
+	req := httptest.NewRequest(method, path, nil)
+	rec := httptest.NewRecorder()
+	props := make(map[string]interface{})
+	res := response(rec, req, &props)
+	freq := request(req)
+
+	routes, ok := r.routes[method]
+	if !ok {
+		return res, false
+	}
+
+	f, params, match := routes.Get(path)
+	if !match {
+		return res, false
+	}
+
+	freq.Params = *params
+	f(res, freq)
+	PutParams(params)
+	return res, true
+}
+
+func TestGroupNestedMiddlewareOrder(t *testing.T) {
+	var order []string
+
+	record := func(name string) Handler {
+		return func(res *Response, req *Request) {
+			order = append(order, name)
+		}
+	}
+
+	r := NewRouter()
+	api := r.Group("/api", record("api"))
+	v1 := api.Group("/v1", record("v1"))
+	v1.Get("/users", record("handler"))
+
+	if _, ok := runRoute(r, http.MethodGet, "/api/v1/users"); !ok {
+		t.Fatal("expected /api/v1/users to match")
+	}
+
+	want := []string{"api", "v1", "handler"}
+	if len(order) != len(want) {
+		t.Fatalf("got middleware order %v, want %v", order, want)
+	}
+	for i, name := range want {
+		if order[i] != name {
+			t.Fatalf("got middleware order %v, want %v", order, want)
+		}
+	}
+}
+
+func TestGroupMiddlewareShortCircuit(t *testing.T) {
+	var ran []string
+
+	r := NewRouter()
+	blocked := r.Group("/admin",
+		func(res *Response, req *Request) {
+			ran = append(ran, "auth")
+			res.Status(http.StatusForbidden).Send("forbidden")
+		},
+		func(res *Response, req *Request) {
+			ran = append(ran, "should-not-run")
+		},
+	)
+	blocked.Get("/dashboard", func(res *Response, req *Request) {
+		ran = append(ran, "handler")
+	})
+
+	if _, ok := runRoute(r, http.MethodGet, "/admin/dashboard"); !ok {
+		t.Fatal("expected /admin/dashboard to match")
+	}
+
+	want := []string{"auth"}
+	if len(ran) != len(want) || ran[0] != want[0] {
+		t.Fatalf("got %v, want the chain to stop after the first middleware ends the response", ran)
+	}
+}